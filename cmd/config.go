@@ -0,0 +1,135 @@
+// Copyright 2023 Chris Wheeler
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// Config holds all settings for a single pm2md run, resolved from (lowest to
+// highest priority) a default, a config file, a PM2MD_* environment
+// variable, and a command-line flag.
+type Config struct {
+	Statuses                   string
+	CustomTmplPath             string
+	Engine                     string
+	OutputDir                  string
+	Split                      string
+	NamePattern                string
+	ConfirmReplaceExistingFile bool
+	// JSONFilePath and DestName come from positional arguments rather than a
+	// flag, env var, or config file, but travel alongside the rest of a run's
+	// settings so convertOnce only needs a single Config argument.
+	JSONFilePath string
+	DestName     string
+	// EnvFiles and VarFlags are repeatable flags (--env, --var), so they're
+	// read directly from their package vars rather than through Viper. Their
+	// variables are resolved into every request's URL, headers, and body
+	// before any template runs; see the --env flag's help text.
+	EnvFiles []string
+	VarFlags []string
+	// OutputFormat selects markdown or an OpenAPI variant; see outputFormats.
+	OutputFormat string
+}
+
+var CfgFile string
+
+// findConfigFile returns the path of the first pm2md config file found, in
+// this order: the --config flag, $PWD/.pm2md.yaml, $XDG_CONFIG_HOME/pm2md/config.yaml,
+// $HOME/.pm2md.yaml. It returns an empty string if none are found.
+func findConfigFile() string {
+	if len(CfgFile) > 0 {
+		return CfgFile
+	}
+
+	var candidates []string
+	if pwd, err := os.Getwd(); err == nil {
+		candidates = append(candidates, filepath.Join(pwd, ".pm2md.yaml"))
+	}
+	if xdgConfigHome := os.Getenv("XDG_CONFIG_HOME"); len(xdgConfigHome) > 0 {
+		candidates = append(candidates, filepath.Join(xdgConfigHome, "pm2md", "config.yaml"))
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		candidates = append(candidates, filepath.Join(home, ".pm2md.yaml"))
+	}
+
+	for _, candidate := range candidates {
+		if FileExists(candidate) {
+			return candidate
+		}
+	}
+	return ""
+}
+
+// initConfig reads a discovered pm2md config file (if any) into Viper and
+// enables PM2MD_* environment variables. It's registered with
+// cobra.OnInitialize so it runs after flags are parsed but before rootCmd.Run.
+func initConfig() {
+	if configFile := findConfigFile(); len(configFile) > 0 {
+		viper.SetConfigFile(configFile)
+		if err := viper.ReadInConfig(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading config file %q: %s\n", configFile, err)
+		}
+	}
+
+	viper.SetEnvPrefix("PM2MD")
+	viper.AutomaticEnv()
+}
+
+// resolveConfig merges cmd's parsed flags with whatever initConfig loaded
+// into Viper, giving flag > env > config file > default precedence, and
+// returns the result as a Config.
+func resolveConfig(cmd *cobra.Command) Config {
+	viper.BindPFlag("statuses", cmd.Flags().Lookup("statuses"))
+	viper.BindPFlag("template", cmd.Flags().Lookup("template"))
+	viper.BindPFlag("engine", cmd.Flags().Lookup("engine"))
+	viper.BindPFlag("output_dir", cmd.Flags().Lookup("output-dir"))
+	viper.BindPFlag("replace", cmd.Flags().Lookup("replace"))
+	viper.BindPFlag("split", cmd.Flags().Lookup("split"))
+	viper.BindPFlag("name_pattern", cmd.Flags().Lookup("name-pattern"))
+	viper.BindPFlag("output_format", cmd.Flags().Lookup("output-format"))
+
+	return Config{
+		Statuses:                   viper.GetString("statuses"),
+		CustomTmplPath:             viper.GetString("template"),
+		Engine:                     viper.GetString("engine"),
+		OutputDir:                  viper.GetString("output_dir"),
+		Split:                      viper.GetString("split"),
+		NamePattern:                viper.GetString("name_pattern"),
+		ConfirmReplaceExistingFile: viper.GetBool("replace"),
+		EnvFiles:                   EnvFiles,
+		VarFlags:                   VarFlags,
+		OutputFormat:               viper.GetString("output_format"),
+	}
+}
+
+// validateConfig checks fields of a resolved Config that argsFunc can't fully
+// validate up front, because they may come from a config file or PM2MD_*
+// environment variable instead of a flag. convertOnce calls this before
+// running the pipeline.
+func validateConfig(cfg Config) error {
+	if len(cfg.Split) > 0 && !splitModes[cfg.Split] {
+		return fmt.Errorf("--split must be one of \"none\", \"folder\", or \"request\", got %q", cfg.Split)
+	}
+	if len(cfg.OutputFormat) > 0 && !outputFormats[cfg.OutputFormat] {
+		return fmt.Errorf("--output-format must be one of \"markdown\", \"openapi\", or \"openapi-yaml\", got %q", cfg.OutputFormat)
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+// Copyright 2023 Chris Wheeler
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// newTestCmd returns a bare cobra.Command with the same flags resolveConfig
+// expects, so precedence can be tested without touching the real rootCmd.
+func newTestCmd() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Flags().String("statuses", "", "")
+	cmd.Flags().String("template", "", "")
+	cmd.Flags().String("engine", "", "")
+	// resolveConfig looks up "output-dir", "name-pattern", and "output-format",
+	// so these use the same hyphenated flag names as rootCmd.
+	cmd.Flags().String("output-dir", "", "")
+	cmd.Flags().Bool("replace", false, "")
+	cmd.Flags().String("split", "none", "")
+	cmd.Flags().String("name-pattern", "", "")
+	cmd.Flags().String("output-format", "markdown", "")
+	return cmd
+}
+
+func TestResolveConfigDefault(t *testing.T) {
+	viper.Reset()
+	cfg := resolveConfig(newTestCmd())
+	if cfg.Statuses != "" {
+		t.Errorf("cfg.Statuses = %q, want \"\"", cfg.Statuses)
+	}
+}
+
+func TestResolveConfigFilePrecedence(t *testing.T) {
+	viper.Reset()
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, ".pm2md.yaml")
+	if err := os.WriteFile(configPath, []byte("statuses: \"200-299\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	viper.SetConfigFile(configPath)
+	if err := viper.ReadInConfig(); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := resolveConfig(newTestCmd())
+	if cfg.Statuses != "200-299" {
+		t.Errorf("cfg.Statuses = %q, want %q", cfg.Statuses, "200-299")
+	}
+}
+
+func TestResolveConfigEnvOverridesFile(t *testing.T) {
+	viper.Reset()
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, ".pm2md.yaml")
+	if err := os.WriteFile(configPath, []byte("statuses: \"200-299\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	viper.SetConfigFile(configPath)
+	if err := viper.ReadInConfig(); err != nil {
+		t.Fatal(err)
+	}
+	viper.SetEnvPrefix("PM2MD")
+	viper.AutomaticEnv()
+	os.Setenv("PM2MD_STATUSES", "400-499")
+	defer os.Unsetenv("PM2MD_STATUSES")
+
+	cfg := resolveConfig(newTestCmd())
+	if cfg.Statuses != "400-499" {
+		t.Errorf("cfg.Statuses = %q, want %q", cfg.Statuses, "400-499")
+	}
+}
+
+func TestResolveConfigFlagOverridesEnv(t *testing.T) {
+	viper.Reset()
+	viper.SetEnvPrefix("PM2MD")
+	viper.AutomaticEnv()
+	os.Setenv("PM2MD_STATUSES", "400-499")
+	defer os.Unsetenv("PM2MD_STATUSES")
+
+	cmd := newTestCmd()
+	if err := cmd.Flags().Set("statuses", "200-299"); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := resolveConfig(cmd)
+	if cfg.Statuses != "200-299" {
+		t.Errorf("cfg.Statuses = %q, want %q", cfg.Statuses, "200-299")
+	}
+}
+
+func TestValidateConfigInvalidSplit(t *testing.T) {
+	// A typo'd or config-file/env-sourced --split value bypasses argsFunc's
+	// flag-only validation, since argsFunc only checks the CLI-bound Split
+	// package var. validateConfig is the safety net that still catches it.
+	err := validateConfig(Config{Split: "folderz"})
+	if err == nil {
+		t.Error("validateConfig(Config{Split: \"folderz\"}) = nil error, want non-nil")
+	}
+}
+
+func TestValidateConfigValidSplit(t *testing.T) {
+	for _, split := range []string{"", "none", "folder", "request"} {
+		if err := validateConfig(Config{Split: split}); err != nil {
+			t.Errorf("validateConfig(Config{Split: %q}) = %q, want nil", split, err)
+		}
+	}
+}
+
+func TestValidateConfigInvalidOutputFormat(t *testing.T) {
+	// As with --split, a typo'd or config-file/env-sourced --output-format
+	// value bypasses argsFunc's flag-only validation.
+	err := validateConfig(Config{OutputFormat: "xml"})
+	if err == nil {
+		t.Error("validateConfig(Config{OutputFormat: \"xml\"}) = nil error, want non-nil")
+	}
+}
+
+func TestValidateConfigValidOutputFormat(t *testing.T) {
+	for _, format := range []string{"", "markdown", "openapi", "openapi-yaml"} {
+		if err := validateConfig(Config{OutputFormat: format}); err != nil {
+			t.Errorf("validateConfig(Config{OutputFormat: %q}) = %q, want nil", format, err)
+		}
+	}
+}
+
+func TestFindConfigFileFromFlag(t *testing.T) {
+	orig := CfgFile
+	defer func() { CfgFile = orig }()
+	CfgFile = "/some/explicit/path.yaml"
+	if got := findConfigFile(); got != CfgFile {
+		t.Errorf("findConfigFile() = %q, want %q", got, CfgFile)
+	}
+}
@@ -17,27 +17,63 @@ package cmd
 import (
 	"encoding/json"
 	"fmt"
-	"html/template"
 	"os"
 	"slices"
 	"strconv"
 	"strings"
 )
 
-// jsonToMdFile converts JSON bytes to markdown, prints the markdown to a file or
-// stdout, and returns the destination's name. If the destination name is "-", output
-// goes to stdout. If the destination's name is empty, a file is created with a unique
-// name based on the given JSON. Only an empty destination name will be changed from
-// what is given before being returned.
-func jsonToMdFile(jsonBytes []byte, destName, tmplName, tmplStr string, statusRanges [][]int, confirmReplaceExistingFile bool) (string, error) {
+// jsonToMdFile converts JSON bytes to markdown (or, per cfg.OutputFormat, to
+// OpenAPI), prints the result to a file or stdout, and returns the
+// destination's name. If the destination name is "-", output goes to stdout.
+// If the destination's name is empty, a file is created with a unique name
+// based on the given JSON. Only an empty destination name will be changed
+// from what is given before being returned. If the output is markdown and
+// cfg.Split is "folder" or "request", destName is ignored, output is written as one file per folder or
+// request under cfg.OutputDir, and the returned name is the generated
+// index.md instead.
+func jsonToMdFile(jsonBytes []byte, destName, tmplName, tmplStr string, statusRanges [][]int, cfg Config) (string, error) {
 	collection, err := parseCollection(jsonBytes)
 	if err != nil {
 		return "", err
 	}
 	filterResponsesByStatus(collection, statusRanges)
 
+	if cfg.OutputFormat == "openapi" || cfg.OutputFormat == "openapi-yaml" {
+		collectionName := collection["info"].(map[string]any)["name"].(string)
+		doc, err := buildOpenAPI(collection)
+		if err != nil {
+			return "", err
+		}
+		return writeOpenAPIFile(doc, destName, collectionName, cfg.OutputFormat, cfg.ConfirmReplaceExistingFile)
+	}
+
+	envVars, err := loadEnvFiles(cfg.EnvFiles)
+	if err != nil {
+		return "", err
+	}
+	varFlags, err := parseVarFlags(cfg.VarFlags)
+	if err != nil {
+		return "", err
+	}
+	variables := mergeVariables(collectionVariables(collection), envVars, varFlags)
+	collection["Env"] = envVars
+	collection["Vars"] = varFlags
+	collection["Variables"] = variables
+	if item, ok := collection["item"]; ok {
+		collection["item"] = resolveItemVars(item, variables)
+	}
+
+	if len(cfg.Split) > 0 && cfg.Split != "none" {
+		targets, err := splitCollection(collection, cfg.Split, cfg.NamePattern)
+		if err != nil {
+			return "", err
+		}
+		return writeSplitTargets(targets, collection, cfg.OutputDir, tmplName, tmplStr, cfg.Engine, cfg.ConfirmReplaceExistingFile)
+	}
+
 	collectionName := collection["info"].(map[string]any)["name"].(string)
-	destFile, destName, err := getDestFile(destName, collectionName, confirmReplaceExistingFile)
+	destFile, destName, err := getDestFile(destName, collectionName, ".md", cfg.ConfirmReplaceExistingFile)
 	if err != nil {
 		return "", err
 	}
@@ -46,7 +82,7 @@ func jsonToMdFile(jsonBytes []byte, destName, tmplName, tmplStr string, statusRa
 		defer destFile.Close()
 	}
 
-	if err = executeTemplate(destFile, collection, tmplName, tmplStr); err != nil {
+	if err = executeTemplate(destFile, collection, tmplName, tmplStr, cfg.Engine); err != nil {
 		destFile.Close()
 		os.Remove(destName)
 		return "", err
@@ -131,11 +167,11 @@ func filterResponsesByStatus(collection map[string]any, statusRanges [][]int) {
 
 // getDestFile gets the destination file and its name. If the given destination name is
 // "-", the destination file is os.Stdout. If the given destination name is empty, a new
-// file is created with a name based on the collection name and the returned name will
-// be different from the given one. If the given destination name refers to an existing
-// file and confirmation to replace an existing file is not given, an error is returned.
-// Any returned file is open.
-func getDestFile(destName, collectionName string, confirmReplaceExistingFile bool) (*os.File, string, error) {
+// file is created with the given extension and a name based on the collection name, and
+// the returned name will be different from the given one. If the given destination name
+// refers to an existing file and confirmation to replace an existing file is not given,
+// an error is returned. Any returned file is open.
+func getDestFile(destName, collectionName, ext string, confirmReplaceExistingFile bool) (*os.File, string, error) {
 	if destName == "-" {
 		return os.Stdout, destName, nil
 	}
@@ -144,7 +180,7 @@ func getDestFile(destName, collectionName string, confirmReplaceExistingFile boo
 		if len(fileName) == 0 {
 			fileName = "collection"
 		}
-		destName = CreateUniqueFileName(fileName, ".md")
+		destName = CreateUniqueFileName(fileName, ext)
 	} else if FileExists(destName) && !confirmReplaceExistingFile {
 		return nil, "", fmt.Errorf("File %q already exists. Run the command again with the --replace flag to confirm replacing it.", destName)
 	}
@@ -156,12 +192,19 @@ func getDestFile(destName, collectionName string, confirmReplaceExistingFile boo
 }
 
 // executeTemplate uses a template and FuncMap to convert the collection to markdown and
-// saves to the given destination file. The destination file is not closed.
-func executeTemplate(destFile *os.File, collection map[string]any, tmplName, tmplStr string) error {
-	tmpl, err := template.New(tmplName).Funcs(funcMap).Parse(tmplStr)
+// saves to the given destination file. The destination file is not closed. The engine
+// used to parse and execute the template is chosen by engineForTmplName.
+func executeTemplate(destFile *os.File, collection map[string]any, tmplName, tmplStr, engineFlag string) error {
+	engineName := engineForTmplName(engineFlag, tmplName)
+	engine, ok := engines[engineName]
+	if !ok {
+		return fmt.Errorf("Unknown template engine %q", engineName)
+	}
+
+	executor, err := engine.Parse(tmplName, tmplStr)
 	if err != nil {
-		return fmt.Errorf("Template parsing error: %s", err)
+		return err
 	}
 
-	return tmpl.Execute(destFile, collection)
+	return executor.Execute(destFile, collection)
 }
@@ -0,0 +1,310 @@
+// Copyright 2023 Chris Wheeler
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"gopkg.in/yaml.v3"
+)
+
+// outputFormats are the valid values of the --output-format flag.
+var outputFormats = map[string]bool{
+	"markdown":     true,
+	"openapi":      true,
+	"openapi-yaml": true,
+}
+
+// buildOpenAPI walks collection's "item" tree and translates every Postman
+// request, and its saved example responses, into an OpenAPI operation. Each
+// operation is grouped by its URL's path and HTTP method.
+func buildOpenAPI(collection map[string]any) (*openapi3.T, error) {
+	info, _ := collection["info"].(map[string]any)
+	name, _ := info["name"].(string)
+	description, _ := info["description"].(string)
+
+	doc := &openapi3.T{
+		OpenAPI: "3.1.0",
+		Info: &openapi3.Info{
+			Title:       name,
+			Description: description,
+			Version:     "1.0.0",
+		},
+		Paths: openapi3.NewPaths(),
+	}
+
+	items, _ := collection["item"].([]any)
+	var walk func(items []any)
+	walk = func(items []any) {
+		for _, itemAny := range items {
+			item, ok := itemAny.(map[string]any)
+			if !ok {
+				continue
+			}
+			if subItems, ok := item["item"].([]any); ok {
+				walk(subItems)
+				continue
+			}
+			addOperation(doc, item)
+		}
+	}
+	walk(items)
+
+	return doc, nil
+}
+
+// addOperation adds the operation for one Postman request (an element of a
+// collection's "item" array) to doc, creating its path if necessary.
+func addOperation(doc *openapi3.T, requestItem map[string]any) {
+	request, ok := requestItem["request"].(map[string]any)
+	if !ok {
+		return
+	}
+
+	method, _ := request["method"].(string)
+	if len(method) == 0 {
+		method = "GET"
+	}
+	name, _ := requestItem["name"].(string)
+	path := requestPath(request)
+
+	op := &openapi3.Operation{
+		Summary:   name,
+		Responses: openapi3.NewResponses(),
+	}
+	if description, ok := request["description"].(string); ok {
+		op.Description = description
+	}
+	for _, headerName := range requestHeaderNames(request) {
+		op.Parameters = append(op.Parameters, &openapi3.ParameterRef{
+			Value: openapi3.NewHeaderParameter(headerName),
+		})
+	}
+	if body := requestBody(request); len(body) > 0 {
+		op.RequestBody = &openapi3.RequestBodyRef{
+			Value: openapi3.NewRequestBody().WithJSONSchema(schemaFromJSON(body)),
+		}
+	}
+
+	addResponses(op, requestItem)
+
+	pathItem := doc.Paths.Value(path)
+	if pathItem == nil {
+		pathItem = &openapi3.PathItem{}
+		doc.Paths.Set(path, pathItem)
+	}
+	pathItem.SetOperation(strings.ToUpper(method), op)
+}
+
+// addResponses translates a request item's saved example responses into
+// op.Responses, keyed by status code, falling back to a generic 200 if the
+// request has no saved examples.
+func addResponses(op *openapi3.Operation, requestItem map[string]any) {
+	responses, _ := requestItem["response"].([]any)
+	for _, responseAny := range responses {
+		response, ok := responseAny.(map[string]any)
+		if !ok {
+			continue
+		}
+		code := 200
+		if c, ok := response["code"].(float64); ok {
+			code = int(c)
+		}
+		description := "Response"
+		if responseName, ok := response["name"].(string); ok && len(responseName) > 0 {
+			description = responseName
+		}
+
+		resp := openapi3.NewResponse().WithDescription(description)
+		if body, ok := response["body"].(string); ok && len(body) > 0 {
+			resp = resp.WithJSONSchema(schemaFromJSON(body))
+		}
+		op.Responses.Set(strconv.Itoa(code), &openapi3.ResponseRef{Value: resp})
+	}
+	if op.Responses.Len() == 0 {
+		op.Responses.Set("200", &openapi3.ResponseRef{Value: openapi3.NewResponse().WithDescription("OK")})
+	}
+}
+
+// requestPath extracts the URL path OpenAPI should group a request's
+// operation under, from a Postman request's "url" field (a raw string or a
+// structured object with a "path" segment array). A Postman ":param" path
+// segment becomes an OpenAPI "{param}" path parameter.
+func requestPath(request map[string]any) string {
+	switch url := request["url"].(type) {
+	case string:
+		return pathFromRawURL(url)
+	case map[string]any:
+		if segments, ok := url["path"].([]any); ok && len(segments) > 0 {
+			return pathFromSegments(segments)
+		}
+		if raw, ok := url["raw"].(string); ok {
+			return pathFromRawURL(raw)
+		}
+	}
+	return "/"
+}
+
+func pathFromSegments(segments []any) string {
+	var parts []string
+	for _, segmentAny := range segments {
+		segment, _ := segmentAny.(string)
+		if strings.HasPrefix(segment, ":") {
+			segment = "{" + strings.TrimPrefix(segment, ":") + "}"
+		}
+		parts = append(parts, segment)
+	}
+	return "/" + strings.Join(parts, "/")
+}
+
+// pathFromRawURL extracts the path portion of a raw URL string, stripping
+// any query string or fragment, so that e.g. "https://api.example.com/users
+// ?active=true" and "https://api.example.com/users?sort=name" both map to
+// the OpenAPI path "/users" instead of fragmenting into one bogus path per
+// distinct query string.
+func pathFromRawURL(raw string) string {
+	if schemeEnd := strings.Index(raw, "://"); schemeEnd >= 0 {
+		raw = raw[schemeEnd+3:]
+	}
+	if end := strings.IndexAny(raw, "?#"); end >= 0 {
+		raw = raw[:end]
+	}
+	if pathStart := strings.Index(raw, "/"); pathStart >= 0 {
+		var segments []any
+		for _, segment := range strings.Split(raw[pathStart+1:], "/") {
+			segments = append(segments, segment)
+		}
+		return pathFromSegments(segments)
+	}
+	return "/"
+}
+
+// requestHeaderNames returns the names of a request's enabled headers.
+func requestHeaderNames(request map[string]any) []string {
+	var names []string
+	headers, _ := request["header"].([]any)
+	for _, headerAny := range headers {
+		header, ok := headerAny.(map[string]any)
+		if !ok {
+			continue
+		}
+		if disabled, _ := header["disabled"].(bool); disabled {
+			continue
+		}
+		if key, ok := header["key"].(string); ok {
+			names = append(names, key)
+		}
+	}
+	return names
+}
+
+// requestBody returns a request's raw body string, if its body mode is "raw".
+func requestBody(request map[string]any) string {
+	body, ok := request["body"].(map[string]any)
+	if !ok {
+		return ""
+	}
+	if mode, _ := body["mode"].(string); mode != "raw" {
+		return ""
+	}
+	raw, _ := body["raw"].(string)
+	return raw
+}
+
+// schemaFromJSON infers an OpenAPI schema from a JSON-encoded example. If
+// body isn't valid JSON, it's treated as an opaque string.
+func schemaFromJSON(body string) *openapi3.Schema {
+	var value any
+	if err := json.Unmarshal([]byte(body), &value); err != nil {
+		return openapi3.NewStringSchema()
+	}
+	return schemaFromValue(value)
+}
+
+// schemaFromValue infers an OpenAPI schema from a decoded JSON value.
+func schemaFromValue(value any) *openapi3.Schema {
+	switch v := value.(type) {
+	case nil:
+		return openapi3.NewSchema()
+	case bool:
+		return openapi3.NewBoolSchema()
+	case float64:
+		if v == float64(int64(v)) {
+			return openapi3.NewIntegerSchema()
+		}
+		return openapi3.NewFloat64Schema()
+	case string:
+		return openapi3.NewStringSchema()
+	case []any:
+		itemSchema := openapi3.NewSchema()
+		if len(v) > 0 {
+			itemSchema = schemaFromValue(v[0])
+		}
+		return openapi3.NewArraySchema().WithItems(itemSchema)
+	case map[string]any:
+		schema := openapi3.NewObjectSchema()
+		for key, propValue := range v {
+			schema = schema.WithProperty(key, schemaFromValue(propValue))
+		}
+		return schema
+	default:
+		return openapi3.NewSchema()
+	}
+}
+
+// writeOpenAPIFile marshals doc as JSON or (if outputFormat is
+// "openapi-yaml") YAML, and writes it the same way jsonToMdFile's markdown
+// path does: to stdout if destName is "-", otherwise to a file named after
+// destName or, if empty, collectionName.
+func writeOpenAPIFile(doc *openapi3.T, destName, collectionName, outputFormat string, confirmReplaceExistingFile bool) (string, error) {
+	jsonBytes, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("json.Marshal: %s", err)
+	}
+
+	ext := ".json"
+	outBytes := jsonBytes
+	if outputFormat == "openapi-yaml" {
+		ext = ".yaml"
+		var generic any
+		if err := json.Unmarshal(jsonBytes, &generic); err != nil {
+			return "", err
+		}
+		if outBytes, err = yaml.Marshal(generic); err != nil {
+			return "", fmt.Errorf("yaml.Marshal: %s", err)
+		}
+	}
+
+	destFile, destName, err := getDestFile(destName, collectionName, ext, confirmReplaceExistingFile)
+	if err != nil {
+		return "", err
+	}
+	if destName != "-" {
+		defer destFile.Close()
+	}
+
+	if _, err := destFile.Write(outBytes); err != nil {
+		destFile.Close()
+		os.Remove(destName)
+		return "", fmt.Errorf("destFile.Write: %s", err)
+	}
+
+	return destName, nil
+}
@@ -0,0 +1,141 @@
+// Copyright 2023 Chris Wheeler
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"testing"
+)
+
+func TestRequestPathFromSegments(t *testing.T) {
+	request := map[string]any{
+		"url": map[string]any{
+			"raw":  "https://api.example.com/users/:id",
+			"path": []any{"users", ":id"},
+		},
+	}
+	ans := requestPath(request)
+	want := "/users/{id}"
+	if ans != want {
+		t.Errorf("requestPath(...) = %q, want %q", ans, want)
+	}
+}
+
+func TestRequestPathFromRawString(t *testing.T) {
+	request := map[string]any{"url": "https://api.example.com/users"}
+	ans := requestPath(request)
+	want := "/users"
+	if ans != want {
+		t.Errorf("requestPath(...) = %q, want %q", ans, want)
+	}
+}
+
+func TestRequestPathFromRawStringStripsQuery(t *testing.T) {
+	request := map[string]any{"url": "https://api.example.com/users?active=true&sort=name"}
+	ans := requestPath(request)
+	want := "/users"
+	if ans != want {
+		t.Errorf("requestPath(...) = %q, want %q", ans, want)
+	}
+}
+
+func TestRequestPathFromRawStringStripsFragment(t *testing.T) {
+	request := map[string]any{"url": "{{baseUrl}}/users#section"}
+	ans := requestPath(request)
+	want := "/users"
+	if ans != want {
+		t.Errorf("requestPath(...) = %q, want %q", ans, want)
+	}
+}
+
+func TestRequestHeaderNamesSkipsDisabled(t *testing.T) {
+	request := map[string]any{
+		"header": []any{
+			map[string]any{"key": "Authorization", "value": "Bearer x"},
+			map[string]any{"key": "X-Disabled", "value": "y", "disabled": true},
+		},
+	}
+	names := requestHeaderNames(request)
+	if len(names) != 1 || names[0] != "Authorization" {
+		t.Errorf("requestHeaderNames(...) = %v, want [\"Authorization\"]", names)
+	}
+}
+
+func TestRequestBodyOnlyRawMode(t *testing.T) {
+	request := map[string]any{"body": map[string]any{"mode": "raw", "raw": `{"a":1}`}}
+	if got := requestBody(request); got != `{"a":1}` {
+		t.Errorf("requestBody(...) = %q, want %q", got, `{"a":1}`)
+	}
+
+	request = map[string]any{"body": map[string]any{"mode": "formdata"}}
+	if got := requestBody(request); got != "" {
+		t.Errorf("requestBody(...) = %q, want \"\"", got)
+	}
+}
+
+func TestBuildOpenAPIAddsPathsAndResponses(t *testing.T) {
+	collection := map[string]any{
+		"info": map[string]any{"name": "Sample API", "description": "A sample."},
+		"item": []any{
+			map[string]any{
+				"name":    "Get users",
+				"request": map[string]any{"method": "GET", "url": "https://api.example.com/users"},
+				"response": []any{
+					map[string]any{"name": "OK", "code": float64(200), "body": `[{"id":1}]`},
+				},
+			},
+		},
+	}
+
+	doc, err := buildOpenAPI(collection)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if doc.Info.Title != "Sample API" {
+		t.Errorf("doc.Info.Title = %q, want %q", doc.Info.Title, "Sample API")
+	}
+
+	pathItem := doc.Paths.Value("/users")
+	if pathItem == nil {
+		t.Fatal("doc.Paths.Value(\"/users\") = nil, want a path item")
+	}
+	if pathItem.Get == nil {
+		t.Fatal("pathItem.Get = nil, want a GET operation")
+	}
+	if pathItem.Get.Responses.Value("200") == nil {
+		t.Error("expected a 200 response on the GET /users operation")
+	}
+}
+
+func TestSchemaFromValueTypes(t *testing.T) {
+	tests := []struct {
+		name  string
+		value any
+	}{
+		{"bool", true},
+		{"integer", float64(42)},
+		{"float", float64(4.2)},
+		{"string", "hello"},
+		{"array", []any{"a", "b"}},
+		{"object", map[string]any{"a": float64(1)}},
+		{"nil", nil},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if schema := schemaFromValue(test.value); schema == nil {
+				t.Error("schemaFromValue(...) = nil, want a schema")
+			}
+		})
+	}
+}
@@ -42,6 +42,14 @@ var Statuses string
 var CustomTmplPath string
 var GetTemplate bool
 var ConfirmReplaceExistingFile bool
+var Engine string
+var OutputDir string
+var Split string
+var NamePattern string
+var Watch bool
+var EnvFiles []string
+var VarFlags []string
+var OutputFormat string
 
 var rootCmd = &cobra.Command{
 	Use:     "pm2md [postman_export.json [output.md]]",
@@ -58,58 +66,69 @@ var rootCmd = &cobra.Command{
 				os.Exit(0)
 			}
 		}
-		jsonFilePath := args[0]
-		var destName string
-		if len(args) == 2 {
-			destName = args[1]
-		}
-		// fmt.Printf("json file path: %q\n", jsonFilePath)
-		// fmt.Printf("output destination: %q\n", destName)
-		// fmt.Printf("statuses: %q\n", Statuses)
-		// fmt.Println("show response names:", ShowResponseNames)
-		// fmt.Println("get template:", GetTemplate)
-		// fmt.Printf("custom template: %q\n", CustomTmplPath)
-
-		statusRanges, err := parseStatusRanges(Statuses)
-		if err != nil {
-			fmt.Fprintln(os.Stderr, err)
-			os.Exit(1)
-		}
 
-		var jsonBytes []byte
-		if jsonFilePath == "-" {
-			jsonBytes, err = ScanStdin()
-		} else {
-			jsonBytes, err = os.ReadFile(jsonFilePath)
-		}
-		if err != nil {
-			fmt.Fprintln(os.Stderr, err)
-			os.Exit(1)
+		cfg := resolveConfig(cmd)
+		cfg.JSONFilePath = args[0]
+		if len(args) == 2 {
+			cfg.DestName = args[1]
 		}
 
-		tmplName, tmplStr, err := loadTmpl(CustomTmplPath)
-		if err != nil {
-			fmt.Fprintln(os.Stderr, err)
-			os.Exit(1)
+		if Watch {
+			if err := watchAndConvert(cfg); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+			return
 		}
 
-		destName, err = jsonToMdFile(
-			jsonBytes,
-			destName,
-			tmplName,
-			tmplStr,
-			statusRanges,
-			ConfirmReplaceExistingFile,
-		)
-		if err != nil {
+		if err := convertOnce(cfg); err != nil {
 			fmt.Fprintln(os.Stderr, err)
 			os.Exit(1)
-		} else if destName != "-" {
-			fmt.Fprintf(os.Stderr, "Created %q\n", destName)
 		}
 	},
 }
 
+// convertOnce runs the full one-shot pm2md pipeline: it reads cfg.JSONFilePath
+// (or stdin, if "-"), loads cfg.CustomTmplPath (or the default template), and
+// converts the result to markdown (or, per cfg.OutputFormat, OpenAPI) via
+// jsonToMdFile. The generated file's name
+// is printed to stderr unless output went to stdout.
+func convertOnce(cfg Config) error {
+	if err := validateConfig(cfg); err != nil {
+		return err
+	}
+
+	var jsonBytes []byte
+	var err error
+	if cfg.JSONFilePath == "-" {
+		jsonBytes, err = ScanStdin()
+	} else {
+		jsonBytes, err = os.ReadFile(cfg.JSONFilePath)
+	}
+	if err != nil {
+		return err
+	}
+
+	statusRanges, err := parseStatusRanges(cfg.Statuses)
+	if err != nil {
+		return err
+	}
+
+	tmplName, tmplStr, err := loadTmpl(cfg.CustomTmplPath)
+	if err != nil {
+		return err
+	}
+
+	destName, err := jsonToMdFile(jsonBytes, cfg.DestName, tmplName, tmplStr, statusRanges, cfg)
+	if err != nil {
+		return err
+	}
+	if destName != "-" {
+		fmt.Fprintf(os.Stderr, "Created %q\n", destName)
+	}
+	return nil
+}
+
 func argsFunc(cmd *cobra.Command, args []string) error {
 	if len(args) == 0 && GetTemplate {
 		return nil
@@ -123,12 +142,34 @@ func argsFunc(cmd *cobra.Command, args []string) error {
 	if args[0] != "-" && !strings.HasSuffix(strings.ToLower(args[0]), ".json") {
 		return fmt.Errorf("%q must be \"-\" or end with \".json\"", args[0])
 	}
-	if len(CustomTmplPath) > 0 && !strings.HasSuffix(CustomTmplPath, ".tmpl") {
-		return fmt.Errorf("%q must end with \".tmpl\"", CustomTmplPath)
+	if len(CustomTmplPath) > 0 && !hasKnownTmplExtension(CustomTmplPath) {
+		return fmt.Errorf("%q must end with \".tmpl\", \".mustache\", or \".hbs\"", CustomTmplPath)
+	}
+	if len(Engine) > 0 {
+		if _, ok := engines[Engine]; !ok {
+			return fmt.Errorf("%q is not a known template engine", Engine)
+		}
+	}
+	if len(Split) > 0 && !splitModes[Split] {
+		return fmt.Errorf("--split must be one of \"none\", \"folder\", or \"request\", got %q", Split)
+	}
+	if len(OutputFormat) > 0 && !outputFormats[OutputFormat] {
+		return fmt.Errorf("--output-format must be one of \"markdown\", \"openapi\", or \"openapi-yaml\", got %q", OutputFormat)
 	}
 	return nil
 }
 
+// hasKnownTmplExtension reports whether path ends with an extension registered
+// in engineExtensions.
+func hasKnownTmplExtension(path string) bool {
+	for ext := range engineExtensions {
+		if strings.HasSuffix(path, ext) {
+			return true
+		}
+	}
+	return false
+}
+
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
 func Execute() {
@@ -139,6 +180,14 @@ func Execute() {
 }
 
 func init() {
+	cobra.OnInitialize(initConfig)
+
+	rootCmd.PersistentFlags().StringVar(
+		&CfgFile,
+		"config",
+		"",
+		"Config file (default: $PWD/.pm2md.yaml, $XDG_CONFIG_HOME/pm2md/config.yaml, or $HOME/.pm2md.yaml)",
+	)
 	rootCmd.Flags().StringVarP(
 		&Statuses,
 		"statuses",
@@ -167,6 +216,65 @@ func init() {
 		"Confirm whether to replace a chosen existing output file",
 	)
 	rootCmd.Flags().MarkHidden("replace")
+	rootCmd.Flags().StringVarP(
+		&Engine,
+		"engine",
+		"e",
+		"",
+		"Template engine to use: \"go\" or \"mustache\" (default: inferred from the template's file extension)",
+	)
+	rootCmd.Flags().StringVarP(
+		&OutputDir,
+		"output-dir",
+		"o",
+		"",
+		"Directory to write --split output files to (a single output file is not affected)",
+	)
+	rootCmd.Flags().StringVar(
+		&Split,
+		"split",
+		"none",
+		"Split output into one file per \"folder\" or \"request\" instead of a single file (default: \"none\")",
+	)
+	rootCmd.Flags().StringVar(
+		&NamePattern,
+		"name-pattern",
+		"",
+		fmt.Sprintf(
+			"Template for split output file names: \".folderSlug\", \".folderPath\" (the full folder breadcrumb, "+
+				"for telling apart same-named folders nested under different parents), and \".requestSlug\" "+
+				"are available (default: %q)",
+			defaultNamePattern,
+		),
+	)
+	rootCmd.Flags().BoolVarP(
+		&Watch,
+		"watch",
+		"w",
+		false,
+		"Watch the collection JSON (and custom template, if any) and regenerate output on change",
+	)
+	rootCmd.Flags().StringArrayVar(
+		&EnvFiles,
+		"env",
+		nil,
+		"A Postman environment/globals export or a key/value YAML or JSON file (repeatable). Its variables "+
+			"replace matching {{var}} placeholders in every request's URL, headers, and body before rendering, "+
+			"and are also exposed to the template itself as .Env/.Variables",
+	)
+	rootCmd.Flags().StringArrayVar(
+		&VarFlags,
+		"var",
+		nil,
+		"A \"key=value\" template variable, overriding the same key from --env (repeatable). See --env's help "+
+			"for how these variables are used",
+	)
+	rootCmd.Flags().StringVar(
+		&OutputFormat,
+		"output-format",
+		"markdown",
+		"Output format: \"markdown\", \"openapi\", or \"openapi-yaml\"",
+	)
 }
 
 // loadTmpl loads a template's name and the template itself into strings. If the given
@@ -0,0 +1,232 @@
+// Copyright 2023 Chris Wheeler
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// defaultNamePattern is used when --split is given without --name-pattern.
+const defaultNamePattern = "{{ .folderSlug }}/{{ .requestSlug }}.md"
+
+// splitModes are the valid values of the --split flag.
+var splitModes = map[string]bool{
+	"none":    true,
+	"folder":  true,
+	"request": true,
+}
+
+// splitTarget is one markdown file that a split run will generate: title is
+// used in the generated index, and items is the subset of the collection's
+// "item" tree (Postman requests) scoped to that file.
+type splitTarget struct {
+	relPath string
+	title   string
+	items   []any
+}
+
+// splitCollection walks collection's "item" tree and groups it into the files
+// described by mode and namePattern. In "folder" mode, every folder that
+// directly contains requests becomes one file holding just those requests. In
+// "request" mode, every request becomes its own file. Each file's path is
+// rendered from namePattern (or defaultNamePattern, if empty) with
+// ".folderSlug", ".folderPath", and ".requestSlug" data; see renderName.
+func splitCollection(collection map[string]any, mode, namePattern string) ([]splitTarget, error) {
+	if len(namePattern) == 0 {
+		namePattern = defaultNamePattern
+	}
+	nameTmpl, err := template.New("name-pattern").Parse(namePattern)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid --name-pattern: %s", err)
+	}
+
+	items, _ := collection["item"].([]any)
+	var targets []splitTarget
+
+	// folderPath is the chain of ancestor folder names leading to the
+	// current items, outermost first, e.g. ["Users", "Admin"]. It's kept
+	// around (not just the immediate parent) so that two folders with the
+	// same leaf name nested under different ancestors, e.g. Users/Admin and
+	// Products/Admin, get distinguishable titles and can opt into distinct
+	// file names via the ".folderPath" name-pattern variable.
+	var walk func(items []any, folderPath []string)
+	walk = func(items []any, folderPath []string) {
+		var folderRequests []any
+		for _, itemAny := range items {
+			item, ok := itemAny.(map[string]any)
+			if !ok {
+				continue
+			}
+			name, _ := item["name"].(string)
+			if subItems, ok := item["item"].([]any); ok {
+				walk(subItems, append(folderPath, name))
+				continue
+			}
+			if mode == "request" {
+				relPath, err := renderName(nameTmpl, folderPath, name)
+				if err == nil {
+					targets = append(targets, splitTarget{relPath: relPath, title: name, items: []any{item}})
+				}
+				continue
+			}
+			folderRequests = append(folderRequests, item)
+		}
+		if mode == "folder" && len(folderRequests) > 0 {
+			folderName := ""
+			if len(folderPath) > 0 {
+				folderName = folderPath[len(folderPath)-1]
+			}
+			relPath, err := renderName(nameTmpl, folderPath, folderName)
+			if err == nil {
+				targets = append(targets, splitTarget{relPath: relPath, title: strings.Join(folderPath, "/"), items: folderRequests})
+			}
+		}
+	}
+	walk(items, nil)
+
+	return targets, nil
+}
+
+// renderName executes nameTmpl with data derived from folderPath (the chain
+// of ancestor folder names, outermost first) and requestLabel, turned into
+// file-name-safe slugs, and returns the resulting relative path. ".folderSlug"
+// is just the immediate parent folder's slug, kept for backward compatibility
+// with defaultNamePattern; ".folderPath" is the full breadcrumb joined with
+// "/", for name patterns that need to tell apart same-named folders nested
+// under different ancestors.
+func renderName(nameTmpl *template.Template, folderPath []string, requestLabel string) (string, error) {
+	folderName := ""
+	if len(folderPath) > 0 {
+		folderName = folderPath[len(folderPath)-1]
+	}
+	folderSlug := FormatFileName(folderName)
+	if len(folderSlug) == 0 {
+		folderSlug = "collection"
+	}
+	requestSlug := FormatFileName(requestLabel)
+	if len(requestSlug) == 0 {
+		requestSlug = "index"
+	}
+
+	pathSlugs := make([]string, len(folderPath))
+	for i, name := range folderPath {
+		slug := FormatFileName(name)
+		if len(slug) == 0 {
+			slug = "collection"
+		}
+		pathSlugs[i] = slug
+	}
+	folderPathSlug := strings.Join(pathSlugs, "/")
+	if len(folderPathSlug) == 0 {
+		folderPathSlug = "collection"
+	}
+
+	var buf strings.Builder
+	data := map[string]any{"folderSlug": folderSlug, "requestSlug": requestSlug, "folderPath": folderPathSlug}
+	if err := nameTmpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return filepath.FromSlash(buf.String()), nil
+}
+
+// writeSplitTargets writes each target to its own file under outputDir
+// (created if missing), applies tmplName/tmplStr to a scoped copy of the
+// collection for each, and writes an index.md under outputDir linking to
+// every generated file. It returns index.md's path, which jsonToMdFile
+// reports as the run's destination. Path collisions between generated files,
+// or with files already on disk, are resolved with CreateUniqueFileName.
+func writeSplitTargets(targets []splitTarget, collection map[string]any, outputDir, tmplName, tmplStr, engineFlag string, confirmReplaceExistingFile bool) (string, error) {
+	if len(outputDir) == 0 {
+		outputDir = "."
+	}
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return "", fmt.Errorf("os.MkdirAll: %s", err)
+	}
+
+	info := collection["info"]
+	usedPaths := map[string]bool{}
+	var indexLines []string
+
+	for _, target := range targets {
+		destPath := uniqueSplitPath(outputDir, target.relPath, usedPaths, confirmReplaceExistingFile)
+		usedPaths[destPath] = true
+
+		if err := os.MkdirAll(filepath.Dir(destPath), 0755); err != nil {
+			return "", fmt.Errorf("os.MkdirAll: %s", err)
+		}
+		destFile, err := os.Create(destPath)
+		if err != nil {
+			return "", fmt.Errorf("os.Create: %s", err)
+		}
+
+		data := map[string]any{
+			"info":      info,
+			"item":      target.items,
+			"title":     target.title,
+			"Env":       collection["Env"],
+			"Vars":      collection["Vars"],
+			"Variables": collection["Variables"],
+		}
+		err = executeTemplate(destFile, data, tmplName, tmplStr, engineFlag)
+		destFile.Close()
+		if err != nil {
+			os.Remove(destPath)
+			return "", err
+		}
+
+		rel, err := filepath.Rel(outputDir, destPath)
+		if err != nil {
+			rel = destPath
+		}
+		indexLines = append(indexLines, fmt.Sprintf("- [%s](%s)", target.title, filepath.ToSlash(rel)))
+	}
+
+	indexPath := filepath.Join(outputDir, "index.md")
+	collectionName := "Collection"
+	if info, ok := info.(map[string]any); ok {
+		if name, ok := info["name"].(string); ok && len(name) > 0 {
+			collectionName = name
+		}
+	}
+	indexContent := fmt.Sprintf("# %s\n\n%s\n", collectionName, strings.Join(indexLines, "\n"))
+	if err := os.WriteFile(indexPath, []byte(indexContent), 0644); err != nil {
+		return "", fmt.Errorf("os.WriteFile: %s", err)
+	}
+
+	return indexPath, nil
+}
+
+// uniqueSplitPath joins outputDir and relPath, then renames the result with
+// CreateUniqueFileName if it collides with a path already used by this run or
+// (absent --replace) a file already on disk.
+func uniqueSplitPath(outputDir, relPath string, usedPaths map[string]bool, confirmReplaceExistingFile bool) string {
+	destPath := filepath.Join(outputDir, relPath)
+	if !usedPaths[destPath] && (confirmReplaceExistingFile || !FileExists(destPath)) {
+		return destPath
+	}
+	ext := filepath.Ext(destPath)
+	base := strings.TrimSuffix(destPath, ext)
+	for {
+		destPath = CreateUniqueFileName(base, ext)
+		if !usedPaths[destPath] {
+			return destPath
+		}
+		base = strings.TrimSuffix(destPath, ext)
+	}
+}
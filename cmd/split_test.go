@@ -0,0 +1,190 @@
+// Copyright 2023 Chris Wheeler
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// nestedCollection has a top-level request, a folder with two requests, and a
+// nested sub-folder with one more request.
+func nestedCollection() map[string]any {
+	return map[string]any{
+		"info": map[string]any{"name": "Nested Collection"},
+		"item": []any{
+			map[string]any{"name": "Top-level request", "request": map[string]any{}, "response": []any{}},
+			map[string]any{
+				"name": "Users",
+				"item": []any{
+					map[string]any{"name": "Get user", "request": map[string]any{}, "response": []any{}},
+					map[string]any{"name": "Create user", "request": map[string]any{}, "response": []any{}},
+					map[string]any{
+						"name": "Admin",
+						"item": []any{
+							map[string]any{"name": "Delete user", "request": map[string]any{}, "response": []any{}},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestSplitCollectionByFolder(t *testing.T) {
+	targets, err := splitCollection(nestedCollection(), "folder", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// one file for the top-level requests, one for Users, one for Users/Admin
+	if len(targets) != 3 {
+		t.Fatalf("len(targets) = %d, want 3", len(targets))
+	}
+
+	var foundUsers, foundAdmin bool
+	for _, target := range targets {
+		if target.title == "Users" {
+			foundUsers = true
+			if len(target.items) != 2 {
+				t.Errorf("Users target has %d items, want 2", len(target.items))
+			}
+		}
+		// The nested Admin folder's title includes its full breadcrumb so it
+		// can be told apart from another, differently-nested Admin folder.
+		if target.title == "Users/Admin" {
+			foundAdmin = true
+			if len(target.items) != 1 {
+				t.Errorf("Users/Admin target has %d items, want 1", len(target.items))
+			}
+		}
+	}
+	if !foundUsers {
+		t.Error("no target generated for the \"Users\" folder")
+	}
+	if !foundAdmin {
+		t.Error("no target generated for the \"Users/Admin\" folder")
+	}
+}
+
+func TestSplitCollectionByFolderDisambiguatesSameLeafName(t *testing.T) {
+	// Two folders named "Admin" nested under different parents used to
+	// collide on title (and, with a name pattern that only used
+	// ".folderSlug", on file name too).
+	collection := map[string]any{
+		"info": map[string]any{"name": "Collection"},
+		"item": []any{
+			map[string]any{
+				"name": "Users",
+				"item": []any{
+					map[string]any{
+						"name": "Admin",
+						"item": []any{
+							map[string]any{"name": "Delete user", "request": map[string]any{}, "response": []any{}},
+						},
+					},
+				},
+			},
+			map[string]any{
+				"name": "Products",
+				"item": []any{
+					map[string]any{
+						"name": "Admin",
+						"item": []any{
+							map[string]any{"name": "Delete product", "request": map[string]any{}, "response": []any{}},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	targets, err := splitCollection(collection, "folder", "{{ .folderPath }}.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(targets) != 2 {
+		t.Fatalf("len(targets) = %d, want 2", len(targets))
+	}
+
+	titles := map[string]bool{}
+	relPaths := map[string]bool{}
+	for _, target := range targets {
+		titles[target.title] = true
+		relPaths[target.relPath] = true
+	}
+	if !titles["Users/Admin"] || !titles["Products/Admin"] {
+		t.Errorf("target titles = %v, want both \"Users/Admin\" and \"Products/Admin\"", titles)
+	}
+	if len(relPaths) != 2 {
+		t.Errorf("relPaths = %v, want two distinct paths when the name pattern uses .folderPath", relPaths)
+	}
+}
+
+func TestSplitCollectionByRequest(t *testing.T) {
+	targets, err := splitCollection(nestedCollection(), "request", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(targets) != 4 {
+		t.Fatalf("len(targets) = %d, want 4", len(targets))
+	}
+	for _, target := range targets {
+		if len(target.items) != 1 {
+			t.Errorf("target %q has %d items, want 1", target.title, len(target.items))
+		}
+	}
+}
+
+func TestSplitCollectionInvalidNamePattern(t *testing.T) {
+	_, err := splitCollection(nestedCollection(), "request", "{{ .unclosed")
+	if err == nil {
+		t.Error("splitCollection with an invalid --name-pattern = nil error, want non-nil")
+	}
+}
+
+func TestWriteSplitTargetsCollisionHandling(t *testing.T) {
+	dir := t.TempDir()
+	collection := nestedCollection()
+	targets := []splitTarget{
+		{relPath: "a.md", title: "First", items: []any{}},
+		{relPath: "a.md", title: "Second", items: []any{}},
+	}
+
+	indexPath, err := writeSplitTargets(targets, collection, dir, defaultTmplName, "{{.title}}", "go", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if indexPath != filepath.Join(dir, "index.md") {
+		t.Errorf("writeSplitTargets() index path = %q, want %q", indexPath, filepath.Join(dir, "index.md"))
+	}
+
+	if !FileExists(filepath.Join(dir, "a.md")) {
+		t.Error("expected a.md to exist")
+	}
+	if !FileExists(filepath.Join(dir, "a(1).md")) {
+		t.Error("expected the second colliding target to be renamed to a(1).md")
+	}
+
+	indexBytes, err := os.ReadFile(indexPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	index := string(indexBytes)
+	if !strings.Contains(index, "First") || !strings.Contains(index, "Second") {
+		t.Errorf("index.md = %q, want it to link to both targets", index)
+	}
+}
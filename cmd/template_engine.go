@@ -0,0 +1,100 @@
+// Copyright 2023 Chris Wheeler
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+
+	"github.com/cbroglie/mustache"
+)
+
+// Executor renders a single parsed template to a writer.
+type Executor interface {
+	Execute(w io.Writer, data any) error
+}
+
+// TemplateEngine parses a template's source into something that can later be
+// executed. Each supported template language implements this interface and
+// registers itself in engines.
+type TemplateEngine interface {
+	Parse(name, src string) (Executor, error)
+}
+
+// engines maps a template engine's name to its implementation. New engines
+// should register themselves here and map their file extensions in
+// engineExtensions, which engineForTmplName reads.
+var engines = map[string]TemplateEngine{
+	"go":       goTemplateEngine{},
+	"mustache": mustacheEngine{},
+}
+
+// engineExtensions maps a template file extension to the name of the engine
+// that should handle it. Extensions not listed here fall back to "go".
+var engineExtensions = map[string]string{
+	".tmpl":     "go",
+	".mustache": "mustache",
+	".hbs":      "mustache",
+}
+
+// engineForTmplName chooses a template engine's name for the given template
+// name, preferring an explicit --engine flag over the name's extension.
+func engineForTmplName(engineFlag, tmplName string) string {
+	if len(engineFlag) > 0 {
+		return engineFlag
+	}
+	for ext, engine := range engineExtensions {
+		if strings.HasSuffix(tmplName, ext) {
+			return engine
+		}
+	}
+	return "go"
+}
+
+// goTemplateEngine parses and executes templates with the standard library's
+// html/template package, using funcMap for its custom functions.
+type goTemplateEngine struct{}
+
+func (goTemplateEngine) Parse(name, src string) (Executor, error) {
+	tmpl, err := template.New(name).Funcs(funcMap).Parse(src)
+	if err != nil {
+		return nil, fmt.Errorf("Template parsing error: %s", err)
+	}
+	return tmpl, nil
+}
+
+// mustacheEngine parses and executes Mustache templates via cbroglie/mustache.
+// It is also used for the Handlebars file extension since pm2md only relies
+// on Handlebars' Mustache-compatible subset (variables, sections, partials).
+type mustacheEngine struct{}
+
+func (mustacheEngine) Parse(name, src string) (Executor, error) {
+	tmpl, err := mustache.ParseString(src)
+	if err != nil {
+		return nil, fmt.Errorf("Template parsing error: %s", err)
+	}
+	return mustacheExecutor{tmpl}, nil
+}
+
+// mustacheExecutor adapts a *mustache.Template to the Executor interface.
+type mustacheExecutor struct {
+	tmpl *mustache.Template
+}
+
+func (e mustacheExecutor) Execute(w io.Writer, data any) error {
+	return e.tmpl.FRender(w, data)
+}
@@ -0,0 +1,98 @@
+// Copyright 2023 Chris Wheeler
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEngineForTmplNameExplicitFlag(t *testing.T) {
+	ans := engineForTmplName("mustache", "collection.tmpl")
+	if ans != "mustache" {
+		t.Errorf("engineForTmplName(\"mustache\", \"collection.tmpl\") = %q, want %q", ans, "mustache")
+	}
+}
+
+func TestEngineForTmplNameFromExtension(t *testing.T) {
+	tests := []struct {
+		tmplName string
+		want     string
+	}{
+		{"collection.tmpl", "go"},
+		{"collection.mustache", "mustache"},
+		{"collection.hbs", "mustache"},
+		{"collection.unknown", "go"},
+	}
+	for _, test := range tests {
+		t.Run(test.tmplName, func(t *testing.T) {
+			if ans := engineForTmplName("", test.tmplName); ans != test.want {
+				t.Errorf("engineForTmplName(\"\", %q) = %q, want %q", test.tmplName, ans, test.want)
+			}
+		})
+	}
+}
+
+func TestGoTemplateEngineParseAndExecute(t *testing.T) {
+	executor, err := (goTemplateEngine{}).Parse("test.tmpl", "# {{ .info.name }}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf strings.Builder
+	data := map[string]any{"info": map[string]any{"name": "Sample"}}
+	if err := executor.Execute(&buf, data); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "# Sample" {
+		t.Errorf("executor.Execute(...) wrote %q, want %q", buf.String(), "# Sample")
+	}
+}
+
+func TestGoTemplateEngineParseError(t *testing.T) {
+	_, err := (goTemplateEngine{}).Parse("test.tmpl", "{{ .unclosed")
+	if err == nil {
+		t.Error("(goTemplateEngine{}).Parse(...) = nil error, want non-nil")
+	}
+}
+
+func TestMustacheEngineParseAndExecute(t *testing.T) {
+	executor, err := (mustacheEngine{}).Parse("test.mustache", "# {{name}}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf strings.Builder
+	data := map[string]any{"name": "Sample"}
+	if err := executor.Execute(&buf, data); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "# Sample" {
+		t.Errorf("executor.Execute(...) wrote %q, want %q", buf.String(), "# Sample")
+	}
+}
+
+func TestMustacheEngineParseError(t *testing.T) {
+	_, err := (mustacheEngine{}).Parse("test.mustache", "{{#unclosed}}")
+	if err == nil {
+		t.Error("(mustacheEngine{}).Parse(...) = nil error, want non-nil")
+	}
+}
+
+func TestEnginesRegistry(t *testing.T) {
+	for _, name := range []string{"go", "mustache"} {
+		if _, ok := engines[name]; !ok {
+			t.Errorf("engines[%q] missing, want a registered TemplateEngine", name)
+		}
+	}
+}
@@ -0,0 +1,172 @@
+// Copyright 2023 Chris Wheeler
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+func init() {
+	funcMap["resolveVars"] = resolveVars
+}
+
+// postmanEnvironment mirrors the shape of a Postman environment or globals
+// JSON export. Its presence is detected by the "_postman_variable_scope"
+// field, which plain key/value files don't have.
+type postmanEnvironment struct {
+	Scope  string `json:"_postman_variable_scope"`
+	Values []struct {
+		Key     string `json:"key"`
+		Value   string `json:"value"`
+		Enabled bool   `json:"enabled"`
+	} `json:"values"`
+}
+
+// parseVarFlags parses a slice of "key=value" strings, as given with
+// --var, into a map.
+func parseVarFlags(varFlags []string) (map[string]string, error) {
+	vars := map[string]string{}
+	for _, kv := range varFlags {
+		key, value, ok := strings.Cut(kv, "=")
+		if !ok {
+			return nil, fmt.Errorf("--var %q must be in \"key=value\" format", kv)
+		}
+		vars[key] = value
+	}
+	return vars, nil
+}
+
+// loadEnvFiles reads and merges the variables of every file in envFiles, in
+// order, so that a later file's values win over an earlier file's.
+func loadEnvFiles(envFiles []string) (map[string]string, error) {
+	vars := map[string]string{}
+	for _, path := range envFiles {
+		fileVars, err := loadEnvFile(path)
+		if err != nil {
+			return nil, err
+		}
+		for key, value := range fileVars {
+			vars[key] = value
+		}
+	}
+	return vars, nil
+}
+
+// loadEnvFile reads one --env file's variables into a map. The file may be a
+// Postman environment or globals export (detected via
+// "_postman_variable_scope"), or a plain key/value YAML or JSON file.
+func loadEnvFile(path string) (map[string]string, error) {
+	fileBytes, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var env postmanEnvironment
+	if err := json.Unmarshal(fileBytes, &env); err == nil && len(env.Scope) > 0 {
+		vars := map[string]string{}
+		for _, value := range env.Values {
+			if value.Enabled {
+				vars[value.Key] = value.Value
+			}
+		}
+		return vars, nil
+	}
+
+	vars := map[string]string{}
+	if err := yaml.Unmarshal(fileBytes, &vars); err != nil {
+		return nil, fmt.Errorf("%s: not a recognized Postman environment or key/value file", path)
+	}
+	return vars, nil
+}
+
+// collectionVariables reads a Postman collection's own top-level "variable"
+// array, if it has one, into a map of variable name to value.
+func collectionVariables(collection map[string]any) map[string]string {
+	vars := map[string]string{}
+	variables, _ := collection["variable"].([]any)
+	for _, variableAny := range variables {
+		variable, ok := variableAny.(map[string]any)
+		if !ok {
+			continue
+		}
+		key, _ := variable["key"].(string)
+		if len(key) > 0 {
+			value, _ := variable["value"].(string)
+			vars[key] = value
+		}
+	}
+	return vars
+}
+
+// mergeVariables combines a collection's own variables with --env and --var
+// variables, giving later sources priority: collection < env < --var.
+func mergeVariables(collectionVars, envVars, varFlags map[string]string) map[string]string {
+	merged := map[string]string{}
+	for key, value := range collectionVars {
+		merged[key] = value
+	}
+	for key, value := range envVars {
+		merged[key] = value
+	}
+	for key, value := range varFlags {
+		merged[key] = value
+	}
+	return merged
+}
+
+// resolveVars replaces every "{{key}}" occurrence in s with vars[key],
+// leaving unresolved placeholders as-is. jsonToMdFile applies this to every
+// string in a collection's "item" tree before executing any template (see
+// resolveItemVars), so a Postman export's own "{{baseUrl}}"-style
+// placeholders are substituted whether or not the template cares about them.
+// It's also registered in funcMap as "resolveVars" so a custom template can
+// apply it to a string of its own choosing, e.g. to a value built up in the
+// template itself. It isn't available in --name-pattern, which is parsed
+// without funcMap.
+func resolveVars(vars map[string]string, s string) string {
+	for key, value := range vars {
+		s = strings.ReplaceAll(s, "{{"+key+"}}", value)
+	}
+	return s
+}
+
+// resolveItemVars walks a collection's "item" tree (or any JSON-shaped value
+// from it) and replaces every "{{key}}" placeholder in its string values with
+// vars[key], in place, so variables from --env/--var and the collection's own
+// "variable" array are resolved in request URLs, headers, and bodies before
+// any template sees them.
+func resolveItemVars(value any, vars map[string]string) any {
+	switch v := value.(type) {
+	case string:
+		return resolveVars(vars, v)
+	case map[string]any:
+		for key, elem := range v {
+			v[key] = resolveItemVars(elem, vars)
+		}
+		return v
+	case []any:
+		for i, elem := range v {
+			v[i] = resolveItemVars(elem, vars)
+		}
+		return v
+	default:
+		return value
+	}
+}
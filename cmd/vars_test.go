@@ -0,0 +1,145 @@
+// Copyright 2023 Chris Wheeler
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseVarFlags(t *testing.T) {
+	vars, err := parseVarFlags([]string{"baseUrl=https://example.com", "apiKey=xyz"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"baseUrl": "https://example.com", "apiKey": "xyz"}
+	if !reflect.DeepEqual(vars, want) {
+		t.Errorf("parseVarFlags(...) = %v, want %v", vars, want)
+	}
+}
+
+func TestParseVarFlagsInvalid(t *testing.T) {
+	_, err := parseVarFlags([]string{"no-equals-sign"})
+	if err == nil {
+		t.Error("parseVarFlags([]string{\"no-equals-sign\"}) = nil error, want non-nil")
+	}
+}
+
+func TestLoadEnvFilePostmanEnvironment(t *testing.T) {
+	vars, err := loadEnvFile("../samples/sample.postman_environment.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"baseUrl": "https://api.example.com", "apiKey": "abc123"}
+	if !reflect.DeepEqual(vars, want) {
+		t.Errorf("loadEnvFile(...) = %v, want %v", vars, want)
+	}
+}
+
+func TestLoadEnvFilePlainYaml(t *testing.T) {
+	vars, err := loadEnvFile("../samples/sample.vars.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"baseUrl": "https://staging.example.com", "timeout": "30"}
+	if !reflect.DeepEqual(vars, want) {
+		t.Errorf("loadEnvFile(...) = %v, want %v", vars, want)
+	}
+}
+
+func TestLoadEnvFileNonexistent(t *testing.T) {
+	_, err := loadEnvFile("nonexistent.yaml")
+	if err == nil {
+		t.Error("loadEnvFile(\"nonexistent.yaml\") = nil error, want non-nil")
+	}
+}
+
+func TestMergeVariablesPrecedence(t *testing.T) {
+	collectionVars := map[string]string{"baseUrl": "https://collection.example.com", "onlyInCollection": "a"}
+	envVars := map[string]string{"baseUrl": "https://env.example.com", "onlyInEnv": "b"}
+	varFlags := map[string]string{"baseUrl": "https://flag.example.com"}
+
+	merged := mergeVariables(collectionVars, envVars, varFlags)
+
+	want := map[string]string{
+		"baseUrl":          "https://flag.example.com",
+		"onlyInCollection": "a",
+		"onlyInEnv":        "b",
+	}
+	if !reflect.DeepEqual(merged, want) {
+		t.Errorf("mergeVariables(...) = %v, want %v", merged, want)
+	}
+}
+
+func TestCollectionVariables(t *testing.T) {
+	collection := map[string]any{
+		"variable": []any{
+			map[string]any{"key": "baseUrl", "value": "https://example.com"},
+			map[string]any{"key": "apiKey", "value": "abc123"},
+		},
+	}
+	vars := collectionVariables(collection)
+	want := map[string]string{"baseUrl": "https://example.com", "apiKey": "abc123"}
+	if !reflect.DeepEqual(vars, want) {
+		t.Errorf("collectionVariables(...) = %v, want %v", vars, want)
+	}
+}
+
+func TestResolveVars(t *testing.T) {
+	vars := map[string]string{"baseUrl": "https://example.com"}
+	ans := resolveVars(vars, "{{baseUrl}}/users")
+	want := "https://example.com/users"
+	if ans != want {
+		t.Errorf("resolveVars(%v, \"{{baseUrl}}/users\") = %q, want %q", vars, ans, want)
+	}
+}
+
+func TestResolveVarsUnresolvedPlaceholder(t *testing.T) {
+	ans := resolveVars(map[string]string{}, "{{missing}}/users")
+	want := "{{missing}}/users"
+	if ans != want {
+		t.Errorf("resolveVars({}, \"{{missing}}/users\") = %q, want %q", ans, want)
+	}
+}
+
+func TestResolveItemVarsWalksNestedStrings(t *testing.T) {
+	vars := map[string]string{"baseUrl": "https://example.com"}
+	item := []any{
+		map[string]any{
+			"name": "Get users",
+			"request": map[string]any{
+				"url": "{{baseUrl}}/users",
+				"header": []any{
+					map[string]any{"key": "Host", "value": "{{baseUrl}}"},
+				},
+			},
+		},
+	}
+
+	ans := resolveItemVars(item, vars)
+
+	ansItem, ok := ans.([]any)
+	if !ok || len(ansItem) != 1 {
+		t.Fatalf("resolveItemVars(...) = %v, want a one-element []any", ans)
+	}
+	request := ansItem[0].(map[string]any)["request"].(map[string]any)
+	if url := request["url"]; url != "https://example.com/users" {
+		t.Errorf("request[\"url\"] = %v, want %q", url, "https://example.com/users")
+	}
+	header := request["header"].([]any)[0].(map[string]any)
+	if host := header["value"]; host != "https://example.com" {
+		t.Errorf("header[\"value\"] = %v, want %q", host, "https://example.com")
+	}
+}
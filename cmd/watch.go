@@ -0,0 +1,133 @@
+// Copyright 2023 Chris Wheeler
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// watchDebounce is how long watchAndConvert waits after a filesystem event
+// before re-running the pipeline, so that editors which write a file in
+// several small writes only trigger one regeneration.
+const watchDebounce = 200 * time.Millisecond
+
+// shouldTriggerRebuild reports whether a filesystem event's operation should
+// trigger a re-run of the pipeline. Metadata-only changes (chmod) are
+// ignored. Write and Create both trigger a rebuild: Create also covers a
+// rename-into-place, which is how fsnotify reports an editor's "write a temp
+// file, then rename it over the original" save strategy.
+func shouldTriggerRebuild(op fsnotify.Op) bool {
+	return op&(fsnotify.Write|fsnotify.Create) != 0
+}
+
+// watchAndConvert runs convertOnce once immediately, then again every time
+// cfg.JSONFilePath or (if set) cfg.CustomTmplPath changes on disk, until
+// interrupted with SIGINT. Errors from convertOnce are printed to stderr but
+// do not stop watching.
+//
+// It watches each file's parent directory rather than the file itself, and
+// matches events by absolute path. Many editors save by writing a temporary
+// file and renaming it over the original (vim's default, among others),
+// which replaces the original path's inode; a watch on the file itself would
+// go silently dead the moment that happens.
+func watchAndConvert(cfg Config) error {
+	if err := convertOnce(cfg); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("fsnotify.NewWatcher: %s", err)
+	}
+	defer watcher.Close()
+
+	watched := map[string]bool{}
+	watchedDirs := map[string]bool{}
+	addWatchedFile := func(path string) error {
+		if len(path) == 0 || path == "-" {
+			return nil
+		}
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			return fmt.Errorf("filepath.Abs(%q): %s", path, err)
+		}
+		watched[abs] = true
+		dir := filepath.Dir(abs)
+		if watchedDirs[dir] {
+			return nil
+		}
+		if err := watcher.Add(dir); err != nil {
+			return fmt.Errorf("watching %q: %s", dir, err)
+		}
+		watchedDirs[dir] = true
+		return nil
+	}
+	if err := addWatchedFile(cfg.JSONFilePath); err != nil {
+		return err
+	}
+	if err := addWatchedFile(cfg.CustomTmplPath); err != nil {
+		return err
+	}
+
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	defer signal.Stop(interrupt)
+
+	fmt.Fprintln(os.Stderr, "Watching for changes. Press Ctrl+C to stop.")
+
+	var debounce *time.Timer
+	defer func() {
+		if debounce != nil {
+			debounce.Stop()
+		}
+	}()
+
+	for {
+		select {
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			abs, err := filepath.Abs(event.Name)
+			if err != nil {
+				abs = event.Name
+			}
+			if !watched[abs] || !shouldTriggerRebuild(event.Op) {
+				continue
+			}
+			if debounce != nil {
+				debounce.Stop()
+			}
+			debounce = time.AfterFunc(watchDebounce, func() {
+				if err := convertOnce(cfg); err != nil {
+					fmt.Fprintln(os.Stderr, err)
+				}
+			})
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			fmt.Fprintln(os.Stderr, err)
+		case <-interrupt:
+			return nil
+		}
+	}
+}
@@ -0,0 +1,168 @@
+// Copyright 2023 Chris Wheeler
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+func TestShouldTriggerRebuild(t *testing.T) {
+	tests := []struct {
+		op   fsnotify.Op
+		want bool
+	}{
+		{fsnotify.Write, true},
+		{fsnotify.Create, true},
+		{fsnotify.Write | fsnotify.Chmod, true},
+		{fsnotify.Chmod, false},
+		{fsnotify.Rename, false},
+		{fsnotify.Remove, false},
+	}
+	for _, test := range tests {
+		if got := shouldTriggerRebuild(test.op); got != test.want {
+			t.Errorf("shouldTriggerRebuild(%v) = %v, want %v", test.op, got, test.want)
+		}
+	}
+}
+
+func TestConvertOnceWritesFile(t *testing.T) {
+	dir := t.TempDir()
+
+	jsonPath := filepath.Join(dir, "collection.json")
+	collectionJSON := `{
+		"info": {
+			"name": "Test",
+			"schema": "https://schema.getpostman.com/json/collection/v2.1.0/collection.json"
+		},
+		"item": []
+	}`
+	if err := os.WriteFile(jsonPath, []byte(collectionJSON), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tmplPath := filepath.Join(dir, "test.tmpl")
+	if err := os.WriteFile(tmplPath, []byte("# {{ .info.name }}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	destPath := filepath.Join(dir, "out.md")
+	cfg := Config{
+		JSONFilePath:   jsonPath,
+		DestName:       destPath,
+		CustomTmplPath: tmplPath,
+		Split:          "none",
+		Engine:         "go",
+	}
+
+	if err := convertOnce(cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := os.ReadFile(destPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != "# Test" {
+		t.Errorf("convertOnce(...) wrote %q, want %q", out, "# Test")
+	}
+}
+
+func TestConvertOnceInvalidConfig(t *testing.T) {
+	cfg := Config{JSONFilePath: "collection.json", Split: "folderz"}
+	if err := convertOnce(cfg); err == nil {
+		t.Error("convertOnce with an invalid Split = nil error, want non-nil")
+	}
+}
+
+// TestWatchAndConvertSurvivesRenameOverWrite drives a real fsnotify watch
+// end to end, including the debounce timer, to make sure watchAndConvert
+// keeps rebuilding after an editor save that writes a temp file and renames
+// it over the watched path instead of writing in place.
+func TestWatchAndConvertSurvivesRenameOverWrite(t *testing.T) {
+	dir := t.TempDir()
+	jsonPath := filepath.Join(dir, "collection.json")
+	tmplPath := filepath.Join(dir, "test.tmpl")
+	destPath := filepath.Join(dir, "out.md")
+
+	collectionJSON := func(name string) string {
+		return fmt.Sprintf(`{
+			"info": {
+				"name": %q,
+				"schema": "https://schema.getpostman.com/json/collection/v2.1.0/collection.json"
+			},
+			"item": []
+		}`, name)
+	}
+	if err := os.WriteFile(jsonPath, []byte(collectionJSON("First")), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(tmplPath, []byte("# {{ .info.name }}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := Config{
+		JSONFilePath:   jsonPath,
+		DestName:       destPath,
+		CustomTmplPath: tmplPath,
+		Split:          "none",
+		Engine:         "go",
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- watchAndConvert(cfg) }()
+
+	waitForContent := func(want string) {
+		t.Helper()
+		deadline := time.Now().Add(2 * time.Second)
+		for time.Now().Before(deadline) {
+			if out, err := os.ReadFile(destPath); err == nil && string(out) == want {
+				return
+			}
+			time.Sleep(20 * time.Millisecond)
+		}
+		t.Fatalf("%s never settled on %q", destPath, want)
+	}
+	waitForContent("# First")
+
+	// Simulate an editor that saves by writing a temp file and renaming it
+	// over the original, rather than writing in place.
+	tmpFile := jsonPath + ".tmp"
+	if err := os.WriteFile(tmpFile, []byte(collectionJSON("Second")), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(tmpFile, jsonPath); err != nil {
+		t.Fatal(err)
+	}
+	waitForContent("# Second")
+
+	if err := syscall.Kill(os.Getpid(), syscall.SIGINT); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("watchAndConvert did not return after SIGINT")
+	}
+}